@@ -18,10 +18,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TencentBlueKing/gopkg/conv"
-	"github.com/parnurzeal/gorequest"
 
 	"github.com/TencentBlueKing/iam-go-sdk/logger"
 	"github.com/TencentBlueKing/iam-go-sdk/util"
@@ -73,6 +73,7 @@ type IAMBackendClient interface {
 	V2PolicyQuery(system string, body interface{}) (data map[string]interface{}, err error)
 	V2PolicyQueryByActions(system string, body interface{}) (data []map[string]interface{}, err error)
 	V2PolicyAuth(system string, body interface{}) (data map[string]interface{}, err error)
+	V2PolicyAuthByActions(system string, body interface{}) (data []map[string]interface{}, err error)
 
 	PolicyAuth(body interface{}) (data map[string]interface{}, err error)
 	PolicyAuthByResources(body interface{}) (data map[string]interface{}, err error)
@@ -83,6 +84,12 @@ type IAMBackendClient interface {
 	PolicySubjects(policyIDs []int64) (data []map[string]interface{}, err error)
 
 	GetApplyURL(body interface{}) (string, error)
+
+	// IssueSessionToken, AuthWithToken and StartSessionTokenRefresher implement the STS-style
+	// session token flow, see sts.go
+	IssueSessionToken(subject string, ttl time.Duration, scopedActions []string) (token string, exp time.Time, err error)
+	AuthWithToken(token string) error
+	StartSessionTokenRefresher(subject string, ttl time.Duration, scopedActions []string) (stop func())
 }
 
 type iamBackendClient struct {
@@ -95,10 +102,36 @@ type iamBackendClient struct {
 
 	isApiDebugEnabled bool
 	isApiForceEnabled bool
+
+	// bkAuth is optional; when set, call will verify appCode/appSecret against BkAuth
+	// before forwarding the request to the iam backend
+	bkAuth BkAuthClient
+
+	transport *httpCaller
+
+	// sessionToken is an optional STS-style short-lived token; while set and unexpired, call
+	// sends it as `Authorization: Bearer <token>` instead of the X-BK-APP-SECRET header.
+	// sessionScopedActions is the decoded policy claim the token was issued for; when
+	// non-empty, call rejects requests whose action falls outside of it
+	sessionTokenMutex    sync.RWMutex
+	sessionToken         string
+	sessionTokenExpiry   time.Time
+	sessionScopedActions []string
 }
 
-// NewIAMBackendClient will create a iam backend client
+// NewIAMBackendClient will create a iam backend client, using DefaultIAMBackendClientOptions
 func NewIAMBackendClient(host string, isAPIGateway bool, system string, appCode string, appSecret string) IAMBackendClient {
+	return NewIAMBackendClientWithOptions(
+		host, isAPIGateway, system, appCode, appSecret, DefaultIAMBackendClientOptions(),
+	)
+}
+
+// NewIAMBackendClientWithOptions will create a iam backend client with a tuned shared http.Client,
+// a retry policy and a per-path circuit breaker, see IAMBackendClientOptions
+func NewIAMBackendClientWithOptions(
+	host string, isAPIGateway bool, system string, appCode string, appSecret string,
+	options IAMBackendClientOptions,
+) IAMBackendClient {
 	host = strings.TrimRight(host, "/")
 	return &iamBackendClient{
 		Host:         host,
@@ -112,9 +145,22 @@ func NewIAMBackendClient(host string, isAPIGateway bool, system string, appCode
 		isApiDebugEnabled: os.Getenv("IAM_API_DEBUG") == "true" || os.Getenv("BKAPP_IAM_API_DEBUG") == "true",
 		// will add ?force=true in url, for api/policy run without cache(all data from database)
 		isApiForceEnabled: os.Getenv("IAM_API_FORCE") == "true" || os.Getenv("BKAPP_IAM_API_FORCE") == "true",
+
+		transport: newHTTPCaller(options),
 	}
 }
 
+// NewIAMBackendClientWithBkAuth will create a iam backend client which verifies app_code/app_secret
+// against a BlueKing BkAuth service (at bkAuthHost) before every call to the iam backend
+func NewIAMBackendClientWithBkAuth(
+	host string, bkAuthHost string, isAPIGateway bool, system string, appCode string, appSecret string,
+) IAMBackendClient {
+	options := DefaultIAMBackendClientOptions()
+	c := NewIAMBackendClientWithOptions(host, isAPIGateway, system, appCode, appSecret, options).(*iamBackendClient)
+	c.bkAuth = newBkAuthClientWithTransport(bkAuthHost, c.transport)
+	return c
+}
+
 func (c *iamBackendClient) call(
 	method Method, path string,
 	data interface{},
@@ -126,11 +172,33 @@ func (c *iamBackendClient) call(
 		callTimeout = defaultTimeout
 	}
 
+	if c.bkAuth != nil {
+		valid, err := c.bkAuth.VerifyAppSecret(c.appCode, c.appSecret)
+		if err != nil {
+			return fmt.Errorf("bkauth verify app_secret fail! err=`%s`", err)
+		}
+		if !valid {
+			return errors.New("bkauth verify app_secret fail! app_code/app_secret not match")
+		}
+	}
+
 	headers := map[string]string{
 		"X-Bk-IAM-Version": bkIAMVersion,
 	}
 
-	if c.IsAPIGateway {
+	if token, scopedActions, ok := c.currentSessionToken(); ok {
+		if len(scopedActions) > 0 {
+			if actionIDs, found := actionIDsFromRequestData(data); found {
+				for _, actionID := range actionIDs {
+					if !containsString(scopedActions, actionID) {
+						return fmt.Errorf("session token is not scoped for action=`%s`", actionID)
+					}
+				}
+			}
+		}
+		headers["X-BK-APP-CODE"] = c.appCode
+		headers["Authorization"] = "Bearer " + token
+	} else if c.IsAPIGateway {
 		auth, err := json.Marshal(map[string]string{
 			"bk_app_code":   c.appCode,
 			"bk_app_secret": c.appSecret,
@@ -145,51 +213,38 @@ func (c *iamBackendClient) call(
 		headers["X-BK-APP-SECRET"] = c.appSecret
 	}
 
-	url := fmt.Sprintf("%s%s", c.Host, path)
+	reqURL, body, err := buildRequestURLAndBody(c.Host, path, method, data, c.isApiDebugEnabled, c.isApiForceEnabled)
+	if err != nil {
+		return fmt.Errorf("build http request fail. err=`%s`", err)
+	}
+
 	start := time.Now()
 	callbackFunc := NewMetricCallback("IAMBackend", start)
 
-	logger.Debugf("do http request: method=`%s`, url=`%s`, data=`%s`", method, url, data)
+	logger.Debugf("do http request: method=`%s`, url=`%s`, data=`%s`", method, reqURL, data)
 
-	// request := gorequest.New().Timeout(callTimeout).Post(url).Type("json")
-	request := gorequest.New().Timeout(callTimeout).Type("json")
-	switch method {
-	case POST:
-		request = request.Post(url).Send(data)
-	case GET:
-		request = request.Get(url).Query(data)
-	}
+	resp, respBody, err := c.transport.doCallWithBreaker(method, path, reqURL, headers, body, callTimeout)
 
-	if c.isApiDebugEnabled {
-		request.QueryData.Add("debug", "true")
-	}
-	if c.isApiForceEnabled {
-		request.QueryData.Add("force", "true")
-	}
+	duration := time.Since(start)
+	logger.Debugf("http request took %v ms", float64(duration/time.Millisecond))
 
-	// set headers
-	for key, value := range headers {
-		request.Header.Set(key, value)
+	if err != nil {
+		callbackFunc(nil, "", []error{err})
+		return fmt.Errorf("http request fail! err=`%s`", err)
 	}
+	callbackFunc(resp, conv.BytesToString(respBody), nil)
 
-	// do request
-	baseResult := IAMBackendBaseResponse{}
-	resp, respBody, errs := request.
-		EndStruct(&baseResult, callbackFunc)
-
-	duration := time.Since(start)
+	logger.Debugf("http response: status_code=%d, body=%+v", resp.StatusCode, conv.BytesToString(respBody))
 
-	logFailHTTPRequest(request, resp, respBody, errs, &baseResult)
+	baseResult := IAMBackendBaseResponse{}
+	if err := json.Unmarshal(respBody, &baseResult); err != nil {
+		return fmt.Errorf("http request response body not valid: %w, body=`%s`", err, respBody)
+	}
 
 	logger.Debugf("http request result: %+v", baseResult.String())
-	logger.Debugf("http request took %v ms", float64(duration/time.Millisecond))
-	logger.Debugf("http response: status_code=%s, body=%+v", resp.StatusCode, conv.BytesToString(respBody))
 
-	if len(errs) != 0 {
-		return fmt.Errorf("gorequest errors=`%s`", errs)
-	}
 	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("gorequest statusCode is %d not 200", resp.StatusCode)
+		err := fmt.Errorf("http request statusCode is %d not 200", resp.StatusCode)
 		if baseResult.Message != "" {
 			err = fmt.Errorf("%w. response body.code: %d, message:%s", err, baseResult.Code, baseResult.Message)
 		}
@@ -201,8 +256,7 @@ func (c *iamBackendClient) call(
 		return fmt.Errorf("response body.code: %d, message:%s", baseResult.Code, baseResult.Message)
 	}
 
-	err := json.Unmarshal(baseResult.Data, responseData)
-	if err != nil {
+	if err := json.Unmarshal(baseResult.Data, responseData); err != nil {
 		return fmt.Errorf("http request response body data not valid: %w, data=`%v`", err, baseResult.Data)
 	}
 	return nil
@@ -238,10 +292,12 @@ func (c *iamBackendClient) callWithReturnSliceMapData(
 func (c *iamBackendClient) Ping() (err error) {
 	url := fmt.Sprintf("%s%s", c.Host, "/ping")
 
-	resp, _, errs := gorequest.New().Timeout(defaultTimeout).Get(url).EndBytes()
-	if len(errs) != 0 {
-		return fmt.Errorf("ping fail! errs=%v", errs)
+	resp, err := c.transport.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("ping fail! err=%v", err)
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("ping fail! status_code=%d", resp.StatusCode)
 	}
@@ -308,6 +364,16 @@ func (c *iamBackendClient) V2PolicyAuth(system string, body interface{}) (data m
 	return
 }
 
+// V2PolicyAuthByActions will do policy auth by actions, returning the per-action
+// allow/deny result set (e.g. `[{"action_id": "...", "is_allowed": true}, ...]`)
+func (c *iamBackendClient) V2PolicyAuthByActions(
+	system string, body interface{},
+) (data []map[string]interface{}, err error) {
+	path := "/api/v2/policy/systems/" + system + "/auth_by_actions/"
+	data, err = c.callWithReturnSliceMapData(POST, path, body, 10)
+	return
+}
+
 // PolicyAuthByResources will do policy auth by resources
 func (c *iamBackendClient) PolicyAuthByResources(body interface{}) (data map[string]interface{}, err error) {
 	path := "/api/v1/policy/auth_by_resources"
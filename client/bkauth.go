@@ -0,0 +1,173 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TencentBlueKing/gopkg/conv"
+
+	"github.com/TencentBlueKing/iam-go-sdk/logger"
+)
+
+// bkAuthVerifyCacheTTL is how long an app_code/app_secret verification result (match or
+// mismatch) is cached for
+const bkAuthVerifyCacheTTL = 60 * time.Second
+
+// AppTenantInfo is the tenant info of an app, as returned by BkAuth
+type AppTenantInfo struct {
+	TenantMode string `json:"tenant_mode"`
+	TenantID   string `json:"tenant_id"`
+}
+
+// BkAuthClient is the interface of the BkAuth client, used to verify app_code/app_secret
+// against a BlueKing BkAuth service
+type BkAuthClient interface {
+	VerifyAppSecret(appCode, appSecret string) (bool, error)
+	GetAppTenantInfo(appCode string) (AppTenantInfo, error)
+}
+
+type bkAuthVerifyCacheEntry struct {
+	valid     bool
+	expiresAt time.Time
+}
+
+type bkAuthClient struct {
+	Host string
+
+	transport *httpCaller
+
+	cacheMutex sync.Mutex
+	cache      map[string]bkAuthVerifyCacheEntry
+}
+
+// NewBkAuthClient will create a standalone bkauth client, used to verify app_code/app_secret
+// via BkAuth
+func NewBkAuthClient(host string, options IAMBackendClientOptions) BkAuthClient {
+	return newBkAuthClientWithTransport(host, newHTTPCaller(options))
+}
+
+// newBkAuthClientWithTransport builds a bkauth client on top of an existing *httpCaller, so
+// NewIAMBackendClientWithBkAuth can have it share the iamBackendClient's connection pool,
+// retries and circuit breaker instead of creating a second set
+func newBkAuthClientWithTransport(host string, transport *httpCaller) BkAuthClient {
+	return &bkAuthClient{
+		Host:      strings.TrimRight(host, "/"),
+		transport: transport,
+		cache:     map[string]bkAuthVerifyCacheEntry{},
+	}
+}
+
+func bkAuthCacheKey(appCode, appSecret string) string {
+	return appCode + ":" + appSecret
+}
+
+func (c *bkAuthClient) getCache(appCode, appSecret string) (valid bool, ok bool) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	entry, exists := c.cache[bkAuthCacheKey(appCode, appSecret)]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+	return entry.valid, true
+}
+
+func (c *bkAuthClient) setCache(appCode, appSecret string, valid bool) {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	c.cache[bkAuthCacheKey(appCode, appSecret)] = bkAuthVerifyCacheEntry{
+		valid:     valid,
+		expiresAt: time.Now().Add(bkAuthVerifyCacheTTL),
+	}
+}
+
+// VerifyAppSecret will verify the app_code/app_secret pair against BkAuth, with a local TTL cache
+// so that hot paths (e.g. every iamBackendClient.call) don't hit BkAuth on every single request
+func (c *bkAuthClient) VerifyAppSecret(appCode, appSecret string) (bool, error) {
+	if valid, ok := c.getCache(appCode, appSecret); ok {
+		return valid, nil
+	}
+
+	path := "/api/v1/apps/access-keys/verify"
+	body := map[string]interface{}{
+		"app_code":   appCode,
+		"app_secret": appSecret,
+	}
+
+	var result struct {
+		Data struct {
+			Valid bool `json:"is_match"`
+		} `json:"data"`
+	}
+
+	if err := c.call(POST, path, body, &result); err != nil {
+		return false, fmt.Errorf("bkauth verify app_secret fail! err=`%s`", err)
+	}
+
+	c.setCache(appCode, appSecret, result.Data.Valid)
+
+	logger.Debugf("bkauth verify app_secret: app_code=`%s`, valid=`%t`", appCode, result.Data.Valid)
+
+	return result.Data.Valid, nil
+}
+
+// GetAppTenantInfo will fetch the tenant info of an app from BkAuth
+func (c *bkAuthClient) GetAppTenantInfo(appCode string) (AppTenantInfo, error) {
+	path := fmt.Sprintf("/api/v1/apps/%s/tenant-info", appCode)
+
+	var result struct {
+		Data AppTenantInfo `json:"data"`
+	}
+
+	if err := c.call(GET, path, map[string]interface{}{}, &result); err != nil {
+		return AppTenantInfo{}, fmt.Errorf("bkauth get app tenant info fail! err=`%s`", err)
+	}
+
+	return result.Data, nil
+}
+
+// call runs an http request through the shared transport and decodes the JSON response body
+// into responseData
+func (c *bkAuthClient) call(method Method, path string, data interface{}, responseData interface{}) error {
+	reqURL, body, err := buildRequestURLAndBody(c.Host, path, method, data, false, false)
+	if err != nil {
+		return fmt.Errorf("build http request fail. err=`%s`", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	start := time.Now()
+	callbackFunc := NewMetricCallback("BkAuth", start)
+
+	resp, respBody, err := c.transport.doCallWithBreaker(method, path, reqURL, headers, body, defaultTimeout)
+	if err != nil {
+		callbackFunc(nil, "", []error{err})
+		return err
+	}
+	callbackFunc(resp, conv.BytesToString(respBody), nil)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status_code=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	if err := json.Unmarshal(respBody, responseData); err != nil {
+		return fmt.Errorf("response body not valid: %w, body=`%s`", err, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBkAuthClientVerifyAppSecretCachesResult(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"is_match": true},
+		})
+	}))
+	defer server.Close()
+
+	c := NewBkAuthClient(server.URL, DefaultIAMBackendClientOptions())
+
+	for i := 0; i < 3; i++ {
+		valid, err := c.VerifyAppSecret("app", "secret")
+		if err != nil {
+			t.Fatalf("VerifyAppSecret() error = %v", err)
+		}
+		if !valid {
+			t.Error("VerifyAppSecret() = false, want true")
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("backend was called %d times, want 1 (cached)", got)
+	}
+}
+
+func TestBkAuthClientGetCacheIsKeyedByAppSecret(t *testing.T) {
+	c := &bkAuthClient{
+		Host:      "http://example.invalid",
+		transport: newHTTPCaller(DefaultIAMBackendClientOptions()),
+		cache:     map[string]bkAuthVerifyCacheEntry{},
+	}
+
+	c.setCache("app", "secret", true)
+
+	if valid, ok := c.getCache("app", "secret"); !ok || !valid {
+		t.Errorf("getCache(app, secret) = (%v, %v), want (true, true)", valid, ok)
+	}
+	if _, ok := c.getCache("app", "other-secret"); ok {
+		t.Error("getCache(app, other-secret) matched a cache entry set for a different secret")
+	}
+}
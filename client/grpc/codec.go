@@ -0,0 +1,38 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package grpc
+
+import "encoding/json"
+
+// jsonCodecName is sent as the content-subtype (content-type application/grpc+json) of every
+// RPC made with jsonCodec, so a gateway built against the same codec can decode it. It is not a standard
+// gRPC content-subtype, so this transport only interoperates with another instance of this
+// package, not with an arbitrary protobuf-speaking gRPC service
+const jsonCodecName = "json"
+
+// jsonCodec marshals RPC messages as JSON instead of protobuf wire format. The message structs
+// in this package (Policy, PolicyQueryRequest, ...) are plain Go structs with json tags, not
+// generated proto.Message implementations, so they can't go through grpc's default proto codec;
+// forcing every call through jsonCodec via grpc.ForceCodec lets them marshal correctly
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return jsonCodecName
+}
@@ -0,0 +1,64 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// appCredentials sends the same X-BK-APP-CODE/X-BK-APP-SECRET (or, for an APIGateway
+// deployment, X-Bkapi-Authorization) identity that the HTTP transport sends as headers,
+// as per-RPC gRPC metadata instead
+type appCredentials struct {
+	appCode      string
+	appSecret    string
+	isAPIGateway bool
+}
+
+// NewAppCredentials builds the per-RPC credential passed via grpc.WithPerRPCCredentials to
+// NewIAMBackendGRPCClient, carrying the app_code/app_secret identity on every RPC
+func NewAppCredentials(appCode string, appSecret string, isAPIGateway bool) *appCredentials {
+	return &appCredentials{
+		appCode:      appCode,
+		appSecret:    appSecret,
+		isAPIGateway: isAPIGateway,
+	}
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (a *appCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if a.isAPIGateway {
+		auth, err := json.Marshal(map[string]string{
+			"bk_app_code":   a.appCode,
+			"bk_app_secret": a.appSecret,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("generate apigateway call metadata fail. err=`%s`", err)
+		}
+		return map[string]string{
+			"x-bkapi-authorization": string(auth),
+		}, nil
+	}
+
+	return map[string]string{
+		"x-bk-app-code":   a.appCode,
+		"x-bk-app-secret": a.appSecret,
+	}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials; the SDK does not force
+// TLS here, callers that need it pass grpc.WithTransportCredentials accordingly
+func (a *appCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,83 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package grpc holds the message/service types mirroring iam.proto. They are plain Go structs
+// with json tags, not protoc-gen-go output: this transport sends them over jsonCodec, not the
+// protobuf wire format, so there is no generated code to regenerate here. iam.proto exists as
+// the service definition of record; keep these types in sync with it by hand.
+package grpc
+
+// Binding associates a Role with the Members it's granted to
+type Binding struct {
+	Role    string   `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Members []string `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+}
+
+// Policy is the full set of bindings in effect for a resource
+type Policy struct {
+	Version  int32      `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Bindings []*Binding `protobuf:"bytes,2,rep,name=bindings,proto3" json:"bindings,omitempty"`
+}
+
+// GetIamPolicyRequest is the request message for IAMPolicy.GetIamPolicy
+type GetIamPolicyRequest struct {
+	Resource string `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+}
+
+// SetIamPolicyRequest is the request message for IAMPolicy.SetIamPolicy
+type SetIamPolicyRequest struct {
+	Resource string  `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Policy   *Policy `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+}
+
+// TestIamPermissionsRequest is the request message for IAMPolicy.TestIamPermissions
+type TestIamPermissionsRequest struct {
+	Resource    string   `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Permissions []string `protobuf:"bytes,2,rep,name=permissions,proto3" json:"permissions,omitempty"`
+}
+
+// TestIamPermissionsResponse is the response message for IAMPolicy.TestIamPermissions
+type TestIamPermissionsResponse struct {
+	Permissions []string `protobuf:"bytes,1,rep,name=permissions,proto3" json:"permissions,omitempty"`
+}
+
+// PolicyQueryRequest wraps the existing JSON policy-query body so the gRPC transport
+// stays a drop-in for callers already building map[string]interface{} bodies
+type PolicyQueryRequest struct {
+	JsonBody []byte `protobuf:"bytes,1,opt,name=json_body,json=jsonBody,proto3" json:"json_body,omitempty"`
+	System   string `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+}
+
+// PolicyQueryResponse carries the JSON-shaped policy data back from bk-iam
+type PolicyQueryResponse struct {
+	JsonData []byte `protobuf:"bytes,1,opt,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+}
+
+// PolicyQueryByActionsRequest is the batched form of PolicyQueryRequest
+type PolicyQueryByActionsRequest struct {
+	JsonBody []byte `protobuf:"bytes,1,opt,name=json_body,json=jsonBody,proto3" json:"json_body,omitempty"`
+	System   string `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+}
+
+// PolicyQueryByActionsResponse is the batched form of PolicyQueryResponse
+type PolicyQueryByActionsResponse struct {
+	JsonData [][]byte `protobuf:"bytes,1,rep,name=json_data,json=jsonData,proto3" json:"json_data,omitempty"`
+}
+
+// GetApplyURLRequest wraps the existing JSON apply-url body
+type GetApplyURLRequest struct {
+	JsonBody []byte `protobuf:"bytes,1,opt,name=json_body,json=jsonBody,proto3" json:"json_body,omitempty"`
+}
+
+// GetApplyURLResponse is the response message for IAMPolicy.GetApplyURL
+type GetApplyURLResponse struct {
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+}
@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	iamPolicyServiceName = "bkiam.v1.IAMPolicy"
+
+	getIamPolicyMethod         = "/" + iamPolicyServiceName + "/GetIamPolicy"
+	setIamPolicyMethod         = "/" + iamPolicyServiceName + "/SetIamPolicy"
+	testIamPermissionsMethod   = "/" + iamPolicyServiceName + "/TestIamPermissions"
+	policyQueryMethod          = "/" + iamPolicyServiceName + "/PolicyQuery"
+	policyQueryByActionsMethod = "/" + iamPolicyServiceName + "/PolicyQueryByActions"
+	getApplyURLMethod          = "/" + iamPolicyServiceName + "/GetApplyURL"
+)
+
+// IAMPolicyClient is the client API for the IAMPolicy service
+type IAMPolicyClient interface {
+	GetIamPolicy(ctx context.Context, in *GetIamPolicyRequest, opts ...grpc.CallOption) (*Policy, error)
+	SetIamPolicy(ctx context.Context, in *SetIamPolicyRequest, opts ...grpc.CallOption) (*Policy, error)
+	TestIamPermissions(
+		ctx context.Context, in *TestIamPermissionsRequest, opts ...grpc.CallOption,
+	) (*TestIamPermissionsResponse, error)
+	PolicyQuery(ctx context.Context, in *PolicyQueryRequest, opts ...grpc.CallOption) (*PolicyQueryResponse, error)
+	PolicyQueryByActions(
+		ctx context.Context, in *PolicyQueryByActionsRequest, opts ...grpc.CallOption,
+	) (*PolicyQueryByActionsResponse, error)
+	GetApplyURL(ctx context.Context, in *GetApplyURLRequest, opts ...grpc.CallOption) (*GetApplyURLResponse, error)
+}
+
+type iamPolicyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewIAMPolicyClient wraps a *grpc.ClientConn (or any grpc.ClientConnInterface) as an
+// IAMPolicyClient
+func NewIAMPolicyClient(cc grpc.ClientConnInterface) IAMPolicyClient {
+	return &iamPolicyClient{cc: cc}
+}
+
+func (c *iamPolicyClient) GetIamPolicy(
+	ctx context.Context, in *GetIamPolicyRequest, opts ...grpc.CallOption,
+) (*Policy, error) {
+	out := new(Policy)
+	if err := c.cc.Invoke(ctx, getIamPolicyMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iamPolicyClient) SetIamPolicy(
+	ctx context.Context, in *SetIamPolicyRequest, opts ...grpc.CallOption,
+) (*Policy, error) {
+	out := new(Policy)
+	if err := c.cc.Invoke(ctx, setIamPolicyMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iamPolicyClient) TestIamPermissions(
+	ctx context.Context, in *TestIamPermissionsRequest, opts ...grpc.CallOption,
+) (*TestIamPermissionsResponse, error) {
+	out := new(TestIamPermissionsResponse)
+	if err := c.cc.Invoke(ctx, testIamPermissionsMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iamPolicyClient) PolicyQuery(
+	ctx context.Context, in *PolicyQueryRequest, opts ...grpc.CallOption,
+) (*PolicyQueryResponse, error) {
+	out := new(PolicyQueryResponse)
+	if err := c.cc.Invoke(ctx, policyQueryMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iamPolicyClient) PolicyQueryByActions(
+	ctx context.Context, in *PolicyQueryByActionsRequest, opts ...grpc.CallOption,
+) (*PolicyQueryByActionsResponse, error) {
+	out := new(PolicyQueryByActionsResponse)
+	if err := c.cc.Invoke(ctx, policyQueryByActionsMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iamPolicyClient) GetApplyURL(
+	ctx context.Context, in *GetApplyURLRequest, opts ...grpc.CallOption,
+) (*GetApplyURLResponse, error) {
+	out := new(GetApplyURLResponse)
+	if err := c.cc.Invoke(ctx, getApplyURLMethod, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package iampb provides typed request/response messages for the IAM policy API,
+// modeled after the resource/binding shape of google.iam.v1, so that callers of
+// IAMBackendClient don't have to hand-unmarshal map[string]interface{} themselves.
+package iampb
+
+// Binding associates a set of subjects (Members) with a Role, scoped to a resource
+type Binding struct {
+	// Role is the action/role ID this binding grants, e.g. "biz_view"
+	Role string `json:"role"`
+	// Members are the subjects this binding applies to, e.g. "user:admin"
+	Members []string `json:"members"`
+}
+
+// Policy is the full access policy for a resource: the set of bindings that apply to it
+type Policy struct {
+	// Version is the policy format version
+	Version int32 `json:"version"`
+	// Bindings is the list of role -> members bindings for the resource
+	Bindings []Binding `json:"bindings"`
+}
+
+// GetIamPolicyRequest is the request for GetIamPolicy
+type GetIamPolicyRequest struct {
+	// Resource is the resource for which the policy is being requested, e.g. "biz:1"
+	Resource string `json:"resource"`
+}
+
+// SetIamPolicyRequest is the request for SetIamPolicy
+type SetIamPolicyRequest struct {
+	// Resource is the resource for which the policy is being specified
+	Resource string `json:"resource"`
+	// Policy is the policy to set
+	Policy Policy `json:"policy"`
+}
+
+// TestIamPermissionsRequest is the request for TestIamPermissions
+type TestIamPermissionsRequest struct {
+	// Resource is the resource for which the permissions are being tested
+	Resource string `json:"resource"`
+	// Permissions are the action IDs to test, e.g. ["biz_view", "biz_edit"]
+	Permissions []string `json:"permissions"`
+}
+
+// TestIamPermissionsResponse is the response for TestIamPermissions
+type TestIamPermissionsResponse struct {
+	// Permissions is the subset of the requested permissions the subject actually holds
+	Permissions []string `json:"permissions"`
+}
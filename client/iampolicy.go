@@ -0,0 +1,130 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TencentBlueKing/iam-go-sdk/client/iampb"
+)
+
+// IAMPolicyClient is a typed wrapper around IAMBackendClient, modeled after google.iam.v1's
+// IAMPolicy service, so callers get Policy/Binding structs instead of map[string]interface{}
+type IAMPolicyClient struct {
+	backend IAMBackendClient
+
+	system  string
+	subject string
+}
+
+// NewIAMPolicyClient will create a typed policy client on top of an existing IAMBackendClient.
+// subject is the current subject (e.g. "user:admin") permissions are tested/queried for
+func NewIAMPolicyClient(backend IAMBackendClient, system string, subject string) *IAMPolicyClient {
+	return &IAMPolicyClient{
+		backend: backend,
+		system:  system,
+		subject: subject,
+	}
+}
+
+// GetIamPolicy will fetch the full policy (the set of role -> members bindings) for a resource
+func (c *IAMPolicyClient) GetIamPolicy(ctx context.Context, resource string) (*iampb.Policy, error) {
+	req := iampb.GetIamPolicyRequest{Resource: resource}
+
+	data, err := c.backend.V2PolicyQuery(c.system, map[string]interface{}{
+		"subject":  c.subject,
+		"resource": req.Resource,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get iam policy fail! err=`%s`", err)
+	}
+
+	policy := &iampb.Policy{}
+	if err := remarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("get iam policy fail! decode response err=`%s`", err)
+	}
+	return policy, nil
+}
+
+// actionAuthResult is one entry of the per-action allow/deny result set V2PolicyAuthByActions
+// returns, e.g. `[{"action_id": "edit", "is_allowed": true}, ...]`
+type actionAuthResult struct {
+	ActionID  string `json:"action_id"`
+	IsAllowed bool   `json:"is_allowed"`
+}
+
+// TestIamPermissions tests the given permissions against a resource, returning the subset
+// of permissions the current subject actually holds. It batches the check into a single
+// V2PolicyAuthByActions call rather than one auth call per permission
+func (c *IAMPolicyClient) TestIamPermissions(
+	ctx context.Context, resource string, permissions []string,
+) ([]string, error) {
+	req := iampb.TestIamPermissionsRequest{
+		Resource:    resource,
+		Permissions: permissions,
+	}
+
+	actions := make([]map[string]interface{}, 0, len(req.Permissions))
+	for _, action := range req.Permissions {
+		actions = append(actions, map[string]interface{}{"id": action})
+	}
+
+	data, err := c.backend.V2PolicyAuthByActions(c.system, map[string]interface{}{
+		"subject":  c.subject,
+		"resource": req.Resource,
+		"actions":  actions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("test iam permissions fail! err=`%s`", err)
+	}
+
+	allowed := make([]string, 0, len(data))
+	for _, item := range data {
+		result := actionAuthResult{}
+		if err := remarshal(item, &result); err != nil {
+			return nil, fmt.Errorf("test iam permissions fail! decode response err=`%s`", err)
+		}
+		if result.IsAllowed {
+			allowed = append(allowed, result.ActionID)
+		}
+	}
+	return allowed, nil
+}
+
+// ListPolicyBindings lists the bindings (role -> members) currently in effect for a resource
+func (c *IAMPolicyClient) ListPolicyBindings(ctx context.Context, resource string) (*iampb.Policy, error) {
+	data, err := c.backend.PolicyList(map[string]interface{}{
+		"system":   c.system,
+		"resource": resource,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list policy bindings fail! err=`%s`", err)
+	}
+
+	policy := &iampb.Policy{}
+	if err := remarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("list policy bindings fail! decode response err=`%s`", err)
+	}
+	return policy, nil
+}
+
+// remarshal converts a generic decoded value (e.g. map[string]interface{}) into a typed
+// struct by round-tripping it through encoding/json
+func remarshal(from interface{}, to interface{}) error {
+	raw, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, to)
+}
@@ -0,0 +1,184 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeIAMBackendClient is a hand-written IAMBackendClient stand-in that lets tests control
+// exactly what each endpoint returns, without standing up an http server
+type fakeIAMBackendClient struct {
+	v2PolicyQueryData          map[string]interface{}
+	v2PolicyQueryErr           error
+	v2PolicyAuthByActionsData  []map[string]interface{}
+	v2PolicyAuthByActionsErr   error
+	policyListData             map[string]interface{}
+	policyListErr              error
+	gotV2PolicyAuthByActionsIn []map[string]interface{}
+}
+
+func (f *fakeIAMBackendClient) Ping() error               { return nil }
+func (f *fakeIAMBackendClient) GetToken() (string, error) { return "", nil }
+func (f *fakeIAMBackendClient) PolicyQuery(body interface{}) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) PolicyQueryByActions(body interface{}) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) V2PolicyQuery(system string, body interface{}) (map[string]interface{}, error) {
+	return f.v2PolicyQueryData, f.v2PolicyQueryErr
+}
+
+func (f *fakeIAMBackendClient) V2PolicyQueryByActions(
+	system string, body interface{},
+) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) V2PolicyAuth(system string, body interface{}) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) V2PolicyAuthByActions(
+	system string, body interface{},
+) ([]map[string]interface{}, error) {
+	if bodyMap, ok := body.(map[string]interface{}); ok {
+		if actions, ok := bodyMap["actions"].([]map[string]interface{}); ok {
+			f.gotV2PolicyAuthByActionsIn = actions
+		}
+	}
+	return f.v2PolicyAuthByActionsData, f.v2PolicyAuthByActionsErr
+}
+
+func (f *fakeIAMBackendClient) PolicyAuth(body interface{}) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) PolicyAuthByResources(body interface{}) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) PolicyAuthByActions(body interface{}) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) PolicyGet(policyID int64) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) PolicyList(body interface{}) (map[string]interface{}, error) {
+	return f.policyListData, f.policyListErr
+}
+
+func (f *fakeIAMBackendClient) PolicySubjects(policyIDs []int64) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeIAMBackendClient) GetApplyURL(body interface{}) (string, error) { return "", nil }
+
+func (f *fakeIAMBackendClient) IssueSessionToken(
+	subject string, ttl time.Duration, scopedActions []string,
+) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
+func (f *fakeIAMBackendClient) AuthWithToken(token string) error { return nil }
+
+func (f *fakeIAMBackendClient) StartSessionTokenRefresher(
+	subject string, ttl time.Duration, scopedActions []string,
+) (stop func()) {
+	return func() {}
+}
+
+func TestIAMPolicyClientTestIamPermissions(t *testing.T) {
+	backend := &fakeIAMBackendClient{
+		v2PolicyAuthByActionsData: []map[string]interface{}{
+			{"action_id": "biz_view", "is_allowed": true},
+			{"action_id": "biz_edit", "is_allowed": false},
+		},
+	}
+	c := NewIAMPolicyClient(backend, "test-system", "user:admin")
+
+	allowed, err := c.TestIamPermissions(context.Background(), "biz:1", []string{"biz_view", "biz_edit"})
+	if err != nil {
+		t.Fatalf("TestIamPermissions() error = %v", err)
+	}
+	if !reflect.DeepEqual(allowed, []string{"biz_view"}) {
+		t.Errorf("TestIamPermissions() = %v, want [biz_view]", allowed)
+	}
+
+	wantActions := []map[string]interface{}{{"id": "biz_view"}, {"id": "biz_edit"}}
+	if !reflect.DeepEqual(backend.gotV2PolicyAuthByActionsIn, wantActions) {
+		t.Errorf("V2PolicyAuthByActions actions = %v, want %v", backend.gotV2PolicyAuthByActionsIn, wantActions)
+	}
+}
+
+func TestIAMPolicyClientTestIamPermissionsError(t *testing.T) {
+	backend := &fakeIAMBackendClient{v2PolicyAuthByActionsErr: errTest}
+	c := NewIAMPolicyClient(backend, "test-system", "user:admin")
+
+	if _, err := c.TestIamPermissions(context.Background(), "biz:1", []string{"biz_view"}); err == nil {
+		t.Error("TestIamPermissions() error = nil, want non-nil")
+	}
+}
+
+func TestIAMPolicyClientGetIamPolicy(t *testing.T) {
+	backend := &fakeIAMBackendClient{
+		v2PolicyQueryData: map[string]interface{}{
+			"version": 1,
+			"bindings": []map[string]interface{}{
+				{"role": "biz_view", "members": []string{"user:admin"}},
+			},
+		},
+	}
+	c := NewIAMPolicyClient(backend, "test-system", "user:admin")
+
+	policy, err := c.GetIamPolicy(context.Background(), "biz:1")
+	if err != nil {
+		t.Fatalf("GetIamPolicy() error = %v", err)
+	}
+	if policy.Version != 1 || len(policy.Bindings) != 1 || policy.Bindings[0].Role != "biz_view" {
+		t.Errorf("GetIamPolicy() = %+v, unexpected shape", policy)
+	}
+}
+
+func TestIAMPolicyClientListPolicyBindings(t *testing.T) {
+	backend := &fakeIAMBackendClient{
+		policyListData: map[string]interface{}{
+			"version": 1,
+			"bindings": []map[string]interface{}{
+				{"role": "biz_edit", "members": []string{"user:admin"}},
+			},
+		},
+	}
+	c := NewIAMPolicyClient(backend, "test-system", "user:admin")
+
+	policy, err := c.ListPolicyBindings(context.Background(), "biz:1")
+	if err != nil {
+		t.Fatalf("ListPolicyBindings() error = %v", err)
+	}
+	if len(policy.Bindings) != 1 || policy.Bindings[0].Role != "biz_edit" {
+		t.Errorf("ListPolicyBindings() = %+v, unexpected shape", policy)
+	}
+}
+
+var errTest = &testError{"backend call failed"}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
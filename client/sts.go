@@ -0,0 +1,241 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/TencentBlueKing/iam-go-sdk/logger"
+)
+
+// defaultSessionTokenRefreshBefore is how long before expiry the background refresher tries
+// to renew a session token
+const defaultSessionTokenRefreshBefore = 30 * time.Second
+
+// IssueSessionToken asks the iam backend to issue a short-lived, JWT-shaped session token
+// (the STS pattern) scoped to subject and a set of actions, so callers don't have to send the
+// raw app_secret on every request. The backend signs the token itself; the SDK never needs to
+// share a signing key, it only decodes the claims it gets back. The scoped actions are carried
+// under the options.PolicyClaimName claim
+func (c *iamBackendClient) IssueSessionToken(
+	subject string, ttl time.Duration, scopedActions []string,
+) (tokenString string, exp time.Time, err error) {
+	path := fmt.Sprintf("/api/v1/model/systems/%s/session_token", c.System)
+	body := map[string]interface{}{
+		"subject":                           subject,
+		"ttl":                               int64(ttl.Seconds()),
+		c.transport.options.PolicyClaimName: scopedActions,
+	}
+
+	data, err := c.callWithReturnMapData(POST, path, body, 10)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("issue session token fail. err=`%s`", err)
+	}
+
+	tokenString, ok := data["token"].(string)
+	if !ok {
+		return "", time.Time{}, errors.New("issue session token fail. no token in response body")
+	}
+
+	expFloat, ok := data["expires_at"].(float64)
+	if !ok {
+		return "", time.Time{}, errors.New("issue session token fail. no expires_at in response body")
+	}
+
+	return tokenString, time.Unix(int64(expFloat), 0), nil
+}
+
+// decodeScopedActions reads the options.PolicyClaimName claim out of claims, accepting either
+// a single string or a list of strings. A missing claim means the token is unscoped (grants
+// every action), matching how app_secret auth has always worked
+func decodeScopedActions(claims jwt.MapClaims, claimName string) ([]string, error) {
+	raw, ok := claims[claimName]
+	if !ok {
+		return nil, nil
+	}
+
+	switch v := raw.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		actions := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("claim `%s` element is not a string: %v", claimName, item)
+			}
+			actions = append(actions, s)
+		}
+		return actions, nil
+	default:
+		return nil, fmt.Errorf("claim `%s` is neither a string nor a list of strings", claimName)
+	}
+}
+
+// AuthWithToken decodes and caches a backend-issued session token; while it remains unexpired,
+// call sends it as `Authorization: Bearer <token>` instead of the X-BK-APP-SECRET header, and
+// enforces its scoped-actions claim (if any) against the action each call targets. The token
+// was already signed by the backend that issued it over an authenticated call, so the SDK
+// decodes its claims without re-verifying a signature it has no key for
+func (c *iamBackendClient) AuthWithToken(token string) error {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return fmt.Errorf("auth with token fail. err=`%s`", err)
+	}
+
+	expFloat, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("auth with token fail. token missing exp claim")
+	}
+
+	scopedActions, err := decodeScopedActions(claims, c.transport.options.PolicyClaimName)
+	if err != nil {
+		return fmt.Errorf("auth with token fail. err=`%s`", err)
+	}
+
+	c.sessionTokenMutex.Lock()
+	c.sessionToken = token
+	c.sessionTokenExpiry = time.Unix(int64(expFloat), 0)
+	c.sessionScopedActions = scopedActions
+	c.sessionTokenMutex.Unlock()
+
+	return nil
+}
+
+// currentSessionToken returns the cached session token and its scoped actions (nil means
+// unscoped), if one is set and not yet expired
+func (c *iamBackendClient) currentSessionToken() (token string, scopedActions []string, ok bool) {
+	c.sessionTokenMutex.RLock()
+	defer c.sessionTokenMutex.RUnlock()
+
+	if c.sessionToken == "" || time.Now().After(c.sessionTokenExpiry) {
+		return "", nil, false
+	}
+	return c.sessionToken, c.sessionScopedActions, true
+}
+
+// clearSessionToken drops the cached session token, so call falls back to the legacy
+// app_secret header path
+func (c *iamBackendClient) clearSessionToken() {
+	c.sessionTokenMutex.Lock()
+	c.sessionToken = ""
+	c.sessionTokenExpiry = time.Time{}
+	c.sessionScopedActions = nil
+	c.sessionTokenMutex.Unlock()
+}
+
+// actionIDsFromRequestData pulls the action id(s) a call targets out of its request body, so
+// call can enforce a session token's scoped-actions claim against it. Request bodies in this
+// SDK carry the action as a top-level "action_id", as an "action": {"id": "..."} map, or, for
+// the *ByActions batch endpoints, as "actions": [{"id": "..."}, ...]; bodies that carry none of
+// these (e.g. GetToken, PolicyList) are treated as administrative and not subject to scoping
+func actionIDsFromRequestData(data interface{}) ([]string, bool) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	if actionID, ok := m["action_id"].(string); ok {
+		return []string{actionID}, true
+	}
+	if action, ok := m["action"].(map[string]interface{}); ok {
+		if actionID, ok := action["id"].(string); ok {
+			return []string{actionID}, true
+		}
+	}
+
+	switch actions := m["actions"].(type) {
+	case []map[string]interface{}:
+		actionIDs := make([]string, 0, len(actions))
+		for _, action := range actions {
+			if actionID, ok := action["id"].(string); ok {
+				actionIDs = append(actionIDs, actionID)
+			}
+		}
+		if len(actionIDs) > 0 {
+			return actionIDs, true
+		}
+	case []interface{}:
+		actionIDs := make([]string, 0, len(actions))
+		for _, raw := range actions {
+			action, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if actionID, ok := action["id"].(string); ok {
+				actionIDs = append(actionIDs, actionID)
+			}
+		}
+		if len(actionIDs) > 0 {
+			return actionIDs, true
+		}
+	}
+
+	return nil, false
+}
+
+func containsString(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+// StartSessionTokenRefresher issues a session token for subject/ttl/scopedActions and keeps
+// renewing it in the background, shortly before each one expires, until stop() is called. If
+// a renewal fails, the cached session token is cleared so call falls back to the legacy
+// app_secret path until the next successful renewal
+func (c *iamBackendClient) StartSessionTokenRefresher(
+	subject string, ttl time.Duration, scopedActions []string,
+) (stop func()) {
+	stopCh := make(chan struct{})
+
+	refresh := func() time.Duration {
+		token, exp, err := c.IssueSessionToken(subject, ttl, scopedActions)
+		if err != nil {
+			logger.Errorf("session token refresh fail, falling back to app_secret. err=`%s`", err)
+			c.clearSessionToken()
+			return defaultSessionTokenRefreshBefore
+		}
+		if err := c.AuthWithToken(token); err != nil {
+			logger.Errorf("session token refresh fail, falling back to app_secret. err=`%s`", err)
+			c.clearSessionToken()
+			return defaultSessionTokenRefreshBefore
+		}
+
+		wait := time.Until(exp) - defaultSessionTokenRefreshBefore
+		if wait <= 0 {
+			wait = defaultSessionTokenRefreshBefore
+		}
+		return wait
+	}
+
+	go func() {
+		wait := refresh()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(wait):
+				wait = refresh()
+			}
+		}
+	}()
+
+	return func() { close(stopCh) }
+}
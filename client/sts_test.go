@@ -0,0 +1,131 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionIDsFromRequestDataSingular(t *testing.T) {
+	got, ok := actionIDsFromRequestData(map[string]interface{}{"action_id": "biz_view"})
+	if !ok || !reflect.DeepEqual(got, []string{"biz_view"}) {
+		t.Errorf("actionIDsFromRequestData() = %v, %v, want [biz_view], true", got, ok)
+	}
+}
+
+func TestActionIDsFromRequestDataNestedAction(t *testing.T) {
+	data := map[string]interface{}{"action": map[string]interface{}{"id": "biz_edit"}}
+	got, ok := actionIDsFromRequestData(data)
+	if !ok || !reflect.DeepEqual(got, []string{"biz_edit"}) {
+		t.Errorf("actionIDsFromRequestData() = %v, %v, want [biz_edit], true", got, ok)
+	}
+}
+
+func TestActionIDsFromRequestDataBatch(t *testing.T) {
+	data := map[string]interface{}{
+		"actions": []map[string]interface{}{{"id": "biz_view"}, {"id": "biz_edit"}},
+	}
+	got, ok := actionIDsFromRequestData(data)
+	want := []string{"biz_view", "biz_edit"}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("actionIDsFromRequestData() = %v, %v, want %v, true", got, ok, want)
+	}
+}
+
+func TestActionIDsFromRequestDataNoActionIsNotScoped(t *testing.T) {
+	if _, ok := actionIDsFromRequestData(map[string]interface{}{"subject": "user:admin"}); ok {
+		t.Error("actionIDsFromRequestData() ok = true, want false for a body without an action")
+	}
+}
+
+func TestCallRejectsSingularActionOutsideScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("backend should not be called for an out-of-scope action")
+	}))
+	defer server.Close()
+
+	c := NewIAMBackendClientWithOptions(
+		server.URL, false, "test-system", "app", "secret", DefaultIAMBackendClientOptions(),
+	).(*iamBackendClient)
+	c.sessionToken = "fake-token"
+	c.sessionTokenExpiry = time.Now().Add(time.Minute)
+	c.sessionScopedActions = []string{"biz_view"}
+
+	var responseData map[string]interface{}
+	err := c.call(POST, "/api/v1/policy/auth", map[string]interface{}{"action_id": "biz_delete"}, 10, &responseData)
+	if err == nil {
+		t.Fatal("call() error = nil, want a scoping error")
+	}
+	if !strings.Contains(err.Error(), "biz_delete") {
+		t.Errorf("call() error = %q, want it to name the out-of-scope action", err)
+	}
+}
+
+func TestCallRejectsBatchActionOutsideScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("backend should not be called when any batched action is out of scope")
+	}))
+	defer server.Close()
+
+	c := NewIAMBackendClientWithOptions(
+		server.URL, false, "test-system", "app", "secret", DefaultIAMBackendClientOptions(),
+	).(*iamBackendClient)
+	c.sessionToken = "fake-token"
+	c.sessionTokenExpiry = time.Now().Add(time.Minute)
+	c.sessionScopedActions = []string{"biz_view"}
+
+	body := map[string]interface{}{
+		"subject":  "user:admin",
+		"resource": "biz:1",
+		"actions":  []map[string]interface{}{{"id": "biz_view"}, {"id": "biz_delete"}},
+	}
+
+	var responseData []map[string]interface{}
+	err := c.call(POST, "/api/v2/policy/systems/test-system/auth_by_actions/", body, 10, &responseData)
+	if err == nil {
+		t.Fatal("call() error = nil, want a scoping error")
+	}
+	if !strings.Contains(err.Error(), "biz_delete") {
+		t.Errorf("call() error = %q, want it to name the out-of-scope action", err)
+	}
+}
+
+func TestCallAllowsBatchActionWithinScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"code":0,"message":"ok","data":[{"action_id":"biz_view","is_allowed":true}]}`))
+	}))
+	defer server.Close()
+
+	c := NewIAMBackendClientWithOptions(
+		server.URL, false, "test-system", "app", "secret", DefaultIAMBackendClientOptions(),
+	).(*iamBackendClient)
+	c.sessionToken = "fake-token"
+	c.sessionTokenExpiry = time.Now().Add(time.Minute)
+	c.sessionScopedActions = []string{"biz_view"}
+
+	body := map[string]interface{}{
+		"subject":  "user:admin",
+		"resource": "biz:1",
+		"actions":  []map[string]interface{}{{"id": "biz_view"}},
+	}
+
+	var responseData []map[string]interface{}
+	if err := c.call(POST, "/api/v2/policy/systems/test-system/auth_by_actions/", body, 10, &responseData); err != nil {
+		t.Fatalf("call() error = %v, want nil for an in-scope action", err)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+
+	"github.com/TencentBlueKing/iam-go-sdk/logger"
+)
+
+const (
+	defaultMaxIdleConnsPerHost = 20
+	defaultIdleConnTimeout     = 90 * time.Second
+
+	defaultMaxRetries       = 2
+	defaultRetryBackoffBase = 100 * time.Millisecond
+
+	defaultCircuitBreakerMaxRequests = 1
+	defaultCircuitBreakerInterval    = 60 * time.Second
+	defaultCircuitBreakerTimeout     = 30 * time.Second
+
+	defaultPolicyClaimName = "policy"
+)
+
+// IAMBackendClientOptions tunes the shared http.Client, retry policy and per-path circuit
+// breakers used by iamBackendClient. Fields are used as given: a zero-valued field is not
+// replaced by its DefaultIAMBackendClientOptions value (e.g. a zero MaxRetries disables retries)
+type IAMBackendClientOptions struct {
+	// MaxIdleConnsPerHost and IdleConnTimeout tune the shared http.Transport so repeated
+	// calls to the same iam backend reuse connections instead of paying a new handshake
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// MaxRetries is the number of extra attempts made for idempotent GETs and for POSTs
+	// that fail with a 5xx or a network timeout
+	MaxRetries int
+	// RetryBackoffBase is the base delay for exponential backoff between retries; a random
+	// jitter up to the computed delay is added to avoid a thundering herd on retry
+	RetryBackoffBase time.Duration
+
+	// CircuitBreakerMaxRequests, CircuitBreakerInterval and CircuitBreakerTimeout configure
+	// the per-path sony/gobreaker.CircuitBreaker guarding calls to the iam backend, see
+	// gobreaker.Settings for their meaning
+	CircuitBreakerMaxRequests uint32
+	CircuitBreakerInterval    time.Duration
+	CircuitBreakerTimeout     time.Duration
+
+	// PolicyClaimName is the claim key session tokens carry their scoped actions under, see
+	// IssueSessionToken
+	PolicyClaimName string
+}
+
+// DefaultIAMBackendClientOptions returns the options used by NewIAMBackendClient
+func DefaultIAMBackendClientOptions() IAMBackendClientOptions {
+	return IAMBackendClientOptions{
+		MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     defaultIdleConnTimeout,
+
+		MaxRetries:       defaultMaxRetries,
+		RetryBackoffBase: defaultRetryBackoffBase,
+
+		CircuitBreakerMaxRequests: defaultCircuitBreakerMaxRequests,
+		CircuitBreakerInterval:    defaultCircuitBreakerInterval,
+		CircuitBreakerTimeout:     defaultCircuitBreakerTimeout,
+
+		PolicyClaimName: defaultPolicyClaimName,
+	}
+}
+
+// WithPolicyClaimName returns a copy of o with PolicyClaimName set to name, e.g.
+//
+//	options := client.DefaultIAMBackendClientOptions().WithPolicyClaimName("roles")
+func (o IAMBackendClientOptions) WithPolicyClaimName(name string) IAMBackendClientOptions {
+	o.PolicyClaimName = name
+	return o
+}
+
+func newHTTPClient(options IAMBackendClientOptions) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = options.IdleConnTimeout
+
+	return &http.Client{Transport: transport}
+}
+
+// httpCaller is the pooled http.Client, retry policy and per-path circuit breakers shared by
+// every http-speaking client in this package
+type httpCaller struct {
+	client  *http.Client
+	options IAMBackendClientOptions
+
+	breakersMutex sync.Mutex
+	breakers      map[string]*gobreaker.CircuitBreaker
+}
+
+func newHTTPCaller(options IAMBackendClientOptions) *httpCaller {
+	return &httpCaller{
+		client:   newHTTPClient(options),
+		options:  options,
+		breakers: map[string]*gobreaker.CircuitBreaker{},
+	}
+}
+
+// getBreaker returns the circuit breaker guarding path, creating it lazily so a stuck
+// endpoint (e.g. /api/v1/policy/auth) can trip open without dragging down unrelated ones
+// (e.g. /ping or the token endpoint)
+func (t *httpCaller) getBreaker(path string) *gobreaker.CircuitBreaker {
+	t.breakersMutex.Lock()
+	defer t.breakersMutex.Unlock()
+
+	if breaker, ok := t.breakers[path]; ok {
+		return breaker
+	}
+
+	breaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        path,
+		MaxRequests: t.options.CircuitBreakerMaxRequests,
+		Interval:    t.options.CircuitBreakerInterval,
+		Timeout:     t.options.CircuitBreakerTimeout,
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			logger.Errorf("iam backend circuit breaker state changed: path=`%s`, from=`%s`, to=`%s`", name, from, to)
+			callbackFunc := NewMetricCallback(fmt.Sprintf("IAMBackend.CircuitBreaker.%s", name), time.Now())
+			callbackFunc(nil, to.String(), nil)
+		},
+	})
+	t.breakers[path] = breaker
+	return breaker
+}
+
+// doCallWithBreaker runs the http request, with doHTTPRequestWithRetry's retries, through the
+// per-path circuit breaker. A 5xx response counts as a breaker failure but is still returned
+// with a nil error, so callers can inspect it; when the breaker is open and rejects the call,
+// no request is made and it returns a nil response and body with an error
+func (t *httpCaller) doCallWithBreaker(
+	method Method, path string, reqURL string, headers map[string]string, body []byte, callTimeout time.Duration,
+) (*http.Response, []byte, error) {
+	breaker := t.getBreaker(path)
+
+	var resp *http.Response
+	var respBody []byte
+	var reqErr error
+
+	_, breakerErr := breaker.Execute(func() (interface{}, error) {
+		resp, respBody, reqErr = t.doHTTPRequestWithRetry(method, reqURL, headers, body, callTimeout)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, fmt.Errorf("iam backend statusCode is %d", resp.StatusCode)
+		}
+		return nil, nil
+	})
+
+	if errors.Is(breakerErr, gobreaker.ErrOpenState) || errors.Is(breakerErr, gobreaker.ErrTooManyRequests) {
+		return nil, nil, fmt.Errorf("iam backend circuit breaker open for path=`%s`: %w", path, breakerErr)
+	}
+	if reqErr != nil {
+		return nil, nil, reqErr
+	}
+	return resp, respBody, nil
+}
+
+// doHTTPRequestWithRetry performs the http call, retrying idempotent GETs and POSTs that come
+// back with a 5xx or a network timeout, up to MaxRetries times with jittered backoff
+func (t *httpCaller) doHTTPRequestWithRetry(
+	method Method, reqURL string, headers map[string]string, body []byte, callTimeout time.Duration,
+) (*http.Response, []byte, error) {
+	var resp *http.Response
+	var respBody []byte
+	var err error
+
+	for attempt := 0; attempt <= t.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			sleepBeforeRetry(t.options.RetryBackoffBase, attempt)
+			logger.Debugf("retrying http request: method=`%s`, url=`%s`, attempt=`%d`", method, reqURL, attempt)
+		}
+
+		resp, respBody, err = t.doHTTPRequest(method, reqURL, headers, body, callTimeout)
+		if err != nil {
+			if attempt < t.options.MaxRetries && isRetryableError(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if resp.StatusCode < http.StatusInternalServerError {
+			return resp, respBody, nil
+		}
+		if attempt == t.options.MaxRetries {
+			return resp, respBody, nil
+		}
+	}
+
+	return resp, respBody, err
+}
+
+func (t *httpCaller) doHTTPRequest(
+	method Method, reqURL string, headers map[string]string, body []byte, callTimeout time.Duration,
+) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, string(method), reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("build http request fail. err=`%s`", err)
+	}
+	for key, value := range headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+	return resp, respBody, nil
+}
+
+func sleepBeforeRetry(backoffBase time.Duration, attempt int) {
+	backoff := backoffBase * time.Duration(int64(1)<<uint(attempt-1))
+	jitter := time.Duration(rand.Int63n(int64(backoff) + 1))
+	time.Sleep(backoff + jitter)
+}
+
+func isRetryableError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// buildRequestURLAndBody turns the (method, data) pair used throughout this package into a
+// concrete request URL and body: GET data becomes query parameters, POST data becomes a JSON
+// body. The debug/force flags are always added as query parameters, matching the old behavior
+func buildRequestURLAndBody(
+	host string, path string, method Method, data interface{}, debugEnabled bool, forceEnabled bool,
+) (string, []byte, error) {
+	u, err := url.Parse(host + path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := u.Query()
+	if debugEnabled {
+		query.Set("debug", "true")
+	}
+	if forceEnabled {
+		query.Set("force", "true")
+	}
+
+	var body []byte
+	switch method {
+	case GET:
+		values, err := queryValuesFromData(data)
+		if err != nil {
+			return "", nil, err
+		}
+		for key, vals := range values {
+			for _, v := range vals {
+				query.Add(key, v)
+			}
+		}
+	case POST:
+		body, err = json.Marshal(data)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), body, nil
+}
+
+// queryValuesFromData converts the request body (always a map[string]interface{} in this SDK)
+// into url.Values by round-tripping it through encoding/json. A slice-valued field is sent as
+// a repeated query param (key=v1&key=v2&...) rather than Go's default %v formatting of the slice
+func queryValuesFromData(data interface{}) (url.Values, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	for key, value := range m {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				values.Add(key, fmt.Sprintf("%v", item))
+			}
+			continue
+		}
+		values.Set(key, fmt.Sprintf("%v", value))
+	}
+	return values, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云-权限中心Go SDK(iam-go-sdk) available.
+ * Copyright (C) 2017-2021 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueryValuesFromDataEncodesSlicesAsRepeatedParams(t *testing.T) {
+	values, err := queryValuesFromData(map[string]interface{}{
+		"ids":    []interface{}{1, 2, 3},
+		"system": "test-system",
+	})
+	if err != nil {
+		t.Fatalf("queryValuesFromData() error = %v", err)
+	}
+
+	if got := values["ids"]; !reflect.DeepEqual(got, []string{"1", "2", "3"}) {
+		t.Errorf("values[\"ids\"] = %v, want [1 2 3]", got)
+	}
+	if got := values.Get("system"); got != "test-system" {
+		t.Errorf("values.Get(\"system\") = %q, want %q", got, "test-system")
+	}
+}
+
+func TestDoHTTPRequestWithRetryRetriesOnServerError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	options := DefaultIAMBackendClientOptions()
+	options.MaxRetries = 2
+	options.RetryBackoffBase = time.Millisecond
+	caller := newHTTPCaller(options)
+
+	resp, _, err := caller.doHTTPRequestWithRetry(GET, server.URL, nil, nil, time.Second)
+	if err != nil {
+		t.Fatalf("doHTTPRequestWithRetry() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestDoCallWithBreakerOpensAfterRepeatedFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	options := DefaultIAMBackendClientOptions()
+	options.MaxRetries = 0
+	caller := newHTTPCaller(options)
+
+	// gobreaker's default ReadyToTrip opens the breaker after more than 5 consecutive failures,
+	// so the 6th call trips it and the 7th is the first one the open breaker rejects
+	var lastErr error
+	for i := 0; i < 7; i++ {
+		_, _, lastErr = caller.doCallWithBreaker(GET, "/fail", server.URL+"/fail", nil, nil, time.Second)
+	}
+
+	if lastErr == nil {
+		t.Fatal("doCallWithBreaker() error = nil, want the breaker to be open after repeated failures")
+	}
+	if got := lastErr.Error(); !strings.Contains(got, "circuit breaker open") {
+		t.Errorf("doCallWithBreaker() error = %q, want it to mention the circuit breaker is open", got)
+	}
+}